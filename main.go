@@ -26,7 +26,6 @@ func main() {
 	}
 
 	tbl := lp.Vectorize(rawLogs)
-	frq := lp.GenerateFrequencyVectors(tbl)
-	lp.FindWordCombinations(frq)
+	lp.FindWordCombinations(tbl)
 
 }
@@ -0,0 +1,46 @@
+package parser
+
+import (
+	"fmt"
+	"testing"
+)
+
+// syntheticHDFSLogs erzeugt n HDFS-artige Log-Zeilen für Benchmarks.
+func syntheticHDFSLogs(n int) []string {
+	logs := make([]string, n)
+	for i := 0; i < n; i++ {
+		logs[i] = fmt.Sprintf("blk_%d info: Block %d received from 10.0.%d.%d", i, i, (i/256)%256, i%256)
+	}
+	return logs
+}
+
+func BenchmarkVectorize(b *testing.B) {
+	rawLogs := syntheticHDFSLogs(100000)
+	regexPatterns := []string{`blk_\d+`, `\d+\.\d+\.\d+\.\d+`, `\d+`}
+
+	b.Run("Serial", func(b *testing.B) {
+		lp, err := NewLogParser(regexPatterns)
+		if err != nil {
+			b.Fatalf("Failed to create parser: %v", err)
+		}
+		lp.Concurrency = 1
+
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			lp.Vectorize(rawLogs)
+		}
+	})
+
+	b.Run("Parallel4", func(b *testing.B) {
+		lp, err := NewLogParser(regexPatterns)
+		if err != nil {
+			b.Fatalf("Failed to create parser: %v", err)
+		}
+		lp.Concurrency = 4
+
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			lp.Vectorize(rawLogs)
+		}
+	})
+}
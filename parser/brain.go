@@ -2,16 +2,35 @@ package parser
 
 import (
 	"regexp"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 )
 
 // LogParser hält die Konfiguration für das Parsing.
 type LogParser struct {
 	RegexPatterns []*regexp.Regexp
+
+	// Threshold ist der LCP-Schwellenwert (siehe GroupByLCP), den
+	// FindWordCombinations und Stream für die InitialGroup-Bildung
+	// verwenden.
+	Threshold float64
+
+	// Concurrency legt fest, in wie viele Shards Vectorize rawLogs für die
+	// parallele Verarbeitung höchstens aufteilt. Werte <= 1 verarbeiten seriell.
+	Concurrency int
+
+	// Metrics speichert pro Signatur die Zeitreihe aus count_over_time/
+	// bytes_over_time Samples, siehe QueryRange.
+	Metrics   map[string]*SampleRing
+	metricsMu sync.Mutex
 }
 
 // NewLogParser initialisiert den Parser mit einer Liste von Regex-Strings.
-func NewLogParser(regexStrings []string) (*LogParser, error) {
+// threshold ist optional und setzt LogParser.Threshold (Standard 0.5), falls
+// angegeben.
+func NewLogParser(regexStrings []string, threshold ...float64) (*LogParser, error) {
 	var patterns []*regexp.Regexp
 	for _, s := range regexStrings {
 		re, err := regexp.Compile(s)
@@ -20,7 +39,13 @@ func NewLogParser(regexStrings []string) (*LogParser, error) {
 		}
 		patterns = append(patterns, re)
 	}
-	return &LogParser{RegexPatterns: patterns}, nil
+
+	t := 0.5
+	if len(threshold) > 0 {
+		t = threshold[0]
+	}
+
+	return &LogParser{RegexPatterns: patterns, Threshold: t}, nil
 }
 
 // Preprocess bereinigt eine Log-Zeile, indem Patterns durch <*> ersetzt werden.
@@ -38,10 +63,14 @@ type LogToken struct {
 	Frequency int
 }
 
-// LogEntry repräsentiert eine verarbeitete Log-Zeile.
+// LogEntry repräsentiert eine verarbeitete Log-Zeile. Timestamp und Bytes
+// werden nur von Stream gesetzt (siehe stream.go) und bleiben beim direkten
+// Vectorize-Aufruf auf ihrem Nullwert.
 type LogEntry struct {
-	LineID int
-	Tokens []LogToken
+	LineID    int
+	Tokens    []LogToken
+	Timestamp time.Time
+	Bytes     int64
 }
 
 // LogGroup fasst Logs gleicher Länge zusammen und speichert Spalten-Statistiken.
@@ -54,11 +83,60 @@ type LogGroup struct {
 }
 
 // Vectorize führt Preprocessing, Tokenisierung und Gruppierung durch.
-// Rückgabe ist eine Map: Länge -> LogGroup
+// Rückgabe ist eine Map: Länge -> LogGroup. Ist LogParser.Concurrency > 1,
+// wird rawLogs in höchstens ebenso viele (und nie mehr als len(rawLogs))
+// Shards der Größe ceil(len/Concurrency) (der letzte ggf. kleiner) aufgeteilt, die parallel
+// verarbeitet und anschließend zu einem Ergebnis reduziert werden.
 func (lp *LogParser) Vectorize(rawLogs []string) map[int]*LogGroup {
+	workers := lp.Concurrency
+	if workers <= 0 {
+		workers = 1
+	}
+	if workers > len(rawLogs) {
+		workers = len(rawLogs)
+	}
+
+	var shardResults []map[int]*LogGroup
+	if workers <= 1 {
+		shardResults = []map[int]*LogGroup{lp.vectorizeShard(rawLogs, 0)}
+	} else {
+		shardResults = make([]map[int]*LogGroup, workers)
+		shardSize := (len(rawLogs) + workers - 1) / workers
+
+		var wg sync.WaitGroup
+		for w := 0; w < workers; w++ {
+			start := w * shardSize
+			if start >= len(rawLogs) {
+				// Mehr Worker als durch shardSize benötigt (Rundungsrest) - nichts zu tun.
+				continue
+			}
+			end := start + shardSize
+			if end > len(rawLogs) {
+				end = len(rawLogs)
+			}
+
+			wg.Add(1)
+			go func(w, start, end int) {
+				defer wg.Done()
+				shardResults[w] = lp.vectorizeShard(rawLogs[start:end], start)
+			}(w, start, end)
+		}
+		wg.Wait()
+	}
+
+	groups := mergeShards(shardResults)
+	writeBackFrequencies(groups)
+	return groups
+}
+
+// vectorizeShard führt Preprocessing, Tokenisierung und Gruppierung für
+// lines durch. offset ist der Index der ersten Zeile im ursprünglichen
+// rawLogs-Slice, damit LineID unabhängig von der Shard-Aufteilung dem
+// Originalindex entspricht.
+func (lp *LogParser) vectorizeShard(lines []string, offset int) map[int]*LogGroup {
 	groups := make(map[int]*LogGroup)
 
-	for id, line := range rawLogs {
+	for i, line := range lines {
 		// 1. Preprocessing
 		cleanLine := lp.Preprocess(line)
 
@@ -84,7 +162,7 @@ func (lp *LogParser) Vectorize(rawLogs []string) map[int]*LogGroup {
 		}
 
 		entry := &LogEntry{
-			LineID: id,
+			LineID: offset + i,
 			Tokens: tokens,
 		}
 		group.Logs = append(group.Logs, entry)
@@ -98,8 +176,49 @@ func (lp *LogParser) Vectorize(rawLogs []string) map[int]*LogGroup {
 		}
 	}
 
-	// Optional: Die ermittelten Häufigkeiten direkt in die LogTokens zurückschreiben
-	// Das erleichtert Schritt 2 (LCP Suche).
+	return groups
+}
+
+// mergeShards fasst die LogGroups mehrerer Shards zusammen, summiert dabei
+// ihre ColumnCounts und sortiert die Logs jeder Gruppe nach LineID, damit
+// das Ergebnis unabhängig von der Shard-Aufteilung deterministisch bleibt.
+func mergeShards(shards []map[int]*LogGroup) map[int]*LogGroup {
+	merged := make(map[int]*LogGroup)
+
+	for _, shard := range shards {
+		for length, g := range shard {
+			mergedGroup, exists := merged[length]
+			if !exists {
+				mergedGroup = &LogGroup{
+					Length:       length,
+					Logs:         []*LogEntry{},
+					ColumnCounts: make(map[int]map[string]int),
+				}
+				merged[length] = mergedGroup
+			}
+
+			mergedGroup.Logs = append(mergedGroup.Logs, g.Logs...)
+			for col, counts := range g.ColumnCounts {
+				if mergedGroup.ColumnCounts[col] == nil {
+					mergedGroup.ColumnCounts[col] = make(map[string]int)
+				}
+				for word, count := range counts {
+					mergedGroup.ColumnCounts[col][word] += count
+				}
+			}
+		}
+	}
+
+	for _, group := range merged {
+		sort.Slice(group.Logs, func(i, j int) bool { return group.Logs[i].LineID < group.Logs[j].LineID })
+	}
+
+	return merged
+}
+
+// writeBackFrequencies schreibt die ermittelten Häufigkeiten direkt in die
+// LogTokens zurück. Das erleichtert Schritt 2 (LCP Suche).
+func writeBackFrequencies(groups map[int]*LogGroup) {
 	for _, group := range groups {
 		for _, log := range group.Logs {
 			for i := range log.Tokens {
@@ -108,8 +227,6 @@ func (lp *LogParser) Vectorize(rawLogs []string) map[int]*LogGroup {
 			}
 		}
 	}
-
-	return groups
 }
 
 // ... (bisheriger Code bleibt unverändert) ...
@@ -120,6 +237,10 @@ type InitialGroup struct {
 	Signature     string      // Eindeutige Signatur des Patterns (z.B. "Info <*> Service")
 	Logs          []*LogEntry // Die Logs in dieser Gruppe
 	RootFrequency int         // Die Häufigkeit der Wörter, die dieses Pattern bilden
+
+	// Samples hält die pro Bucket aggregierten Zeilen-/Byte-Zähler dieser
+	// Signatur, siehe QueryRange.
+	Samples *SampleRing
 }
 
 // GroupByLCP führt Schritt 2 des Brain-Algorithmus aus.
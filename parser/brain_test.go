@@ -4,6 +4,45 @@ import (
 	"testing"
 )
 
+func TestVectorizeConcurrentShardBoundsDontPanic(t *testing.T) {
+	// Regression: len(rawLogs) nicht durch Concurrency teilbar durfte bisher
+	// dazu führen, dass der letzte Shard mit start > end panickt.
+	cases := []struct {
+		name        string
+		lineCount   int
+		concurrency int
+	}{
+		{name: "5 lines / 4 workers", lineCount: 5, concurrency: 4},
+		{name: "1 line / 4 workers", lineCount: 1, concurrency: 4},
+		{name: "3 lines / 8 workers", lineCount: 3, concurrency: 8},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rawLogs := make([]string, tc.lineCount)
+			for i := range rawLogs {
+				rawLogs[i] = "blk_1 info: Block 1 received from 10.0.0.1"
+			}
+
+			lp, err := NewLogParser([]string{`blk_\d+`, `\d+\.\d+\.\d+\.\d+`, `\d+`})
+			if err != nil {
+				t.Fatalf("Failed to create parser: %v", err)
+			}
+			lp.Concurrency = tc.concurrency
+
+			groups := lp.Vectorize(rawLogs)
+
+			total := 0
+			for _, g := range groups {
+				total += len(g.Logs)
+			}
+			if total != tc.lineCount {
+				t.Errorf("expected %d logs total, got %d", tc.lineCount, total)
+			}
+		})
+	}
+}
+
 func TestVectorize(t *testing.T) {
 	// Beispiel-Logs
 	rawLogs := []string{
@@ -0,0 +1,168 @@
+package parser
+
+import "container/heap"
+
+// SampleIterator durchläuft die Samples einer Zeitreihe aufsteigend sortiert
+// nach Zeitstempel. Next muss vor dem ersten At-Aufruf aufgerufen werden.
+type SampleIterator interface {
+	Next() bool
+	At() Sample
+	Labels() string
+	Close() error
+}
+
+// initialGroupIterator iteriert über die Samples einer einzelnen InitialGroup.
+type initialGroupIterator struct {
+	signature string
+	samples   []Sample
+	pos       int
+}
+
+// NewInitialGroupIterator erstellt einen SampleIterator über die Samples von
+// g, beschriftet mit dessen Signatur.
+func NewInitialGroupIterator(g *InitialGroup) SampleIterator {
+	it := &initialGroupIterator{signature: g.Signature, pos: -1}
+	if g.Samples != nil {
+		it.samples = g.Samples.samples
+	}
+	return it
+}
+
+func (it *initialGroupIterator) Next() bool {
+	it.pos++
+	return it.pos < len(it.samples)
+}
+
+func (it *initialGroupIterator) At() Sample { return it.samples[it.pos] }
+
+func (it *initialGroupIterator) Labels() string { return it.signature }
+
+func (it *initialGroupIterator) Close() error { return nil }
+
+// iteratorHeap ordnet SampleIterators nach (Zeitstempel, Signatur) ihres
+// aktuellen Samples, für den Heap-basierten k-way Merge in mergeIterator.
+// Die Signatur geht als Tiebreaker mit ein, damit alle Iteratoren mit
+// identischem Zeitstempel UND Signatur beim Pop direkt hintereinander
+// auftauchen - sonst kann ein dritter Iterator mit anderer Signatur, aber
+// gleichem Zeitstempel, zwei zusammengehörige Samples auseinanderreißen.
+type iteratorHeap []SampleIterator
+
+func (h iteratorHeap) Len() int { return len(h) }
+
+func (h iteratorHeap) Less(i, j int) bool {
+	si, sj := h[i].At(), h[j].At()
+	if !si.Timestamp.Equal(sj.Timestamp) {
+		return si.Timestamp.Before(sj.Timestamp)
+	}
+	return h[i].Labels() < h[j].Labels()
+}
+
+func (h iteratorHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *iteratorHeap) Push(x interface{}) { *h = append(*h, x.(SampleIterator)) }
+
+func (h *iteratorHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	*h = old[:n-1]
+	return item
+}
+
+// mergeIterator führt einen Heap-basierten k-way Merge über mehrere
+// SampleIterators aus und summiert Samples, die sowohl Zeitstempel als auch
+// Signatur gemeinsam haben.
+type mergeIterator struct {
+	h       *iteratorHeap
+	current Sample
+	labels  string
+}
+
+// NewMergeIterator kombiniert mehrere, bereits nach Zeitstempel sortierte
+// SampleIterators zu einem einzigen sortierten SampleIterator.
+func NewMergeIterator(iters ...SampleIterator) SampleIterator {
+	h := &iteratorHeap{}
+	heap.Init(h)
+	for _, it := range iters {
+		if it.Next() {
+			heap.Push(h, it)
+		}
+	}
+	return &mergeIterator{h: h}
+}
+
+func (m *mergeIterator) Next() bool {
+	if m.h.Len() == 0 {
+		return false
+	}
+
+	top := heap.Pop(m.h).(SampleIterator)
+	m.current = top.At()
+	m.labels = top.Labels()
+	if top.Next() {
+		heap.Push(m.h, top)
+	}
+
+	// Weitere Iteratoren mit identischem Zeitstempel und Signatur aufsummieren.
+	for m.h.Len() > 0 {
+		next := (*m.h)[0]
+		sample := next.At()
+		if !sample.Timestamp.Equal(m.current.Timestamp) || next.Labels() != m.labels {
+			break
+		}
+		heap.Pop(m.h)
+		m.current.Count += sample.Count
+		m.current.Bytes += sample.Bytes
+		if next.Next() {
+			heap.Push(m.h, next)
+		}
+	}
+
+	return true
+}
+
+func (m *mergeIterator) At() Sample { return m.current }
+
+func (m *mergeIterator) Labels() string { return m.labels }
+
+func (m *mergeIterator) Close() error {
+	for m.h.Len() > 0 {
+		it := heap.Pop(m.h).(SampleIterator)
+		if err := it.Close(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// BatchIterator liefert die Samples eines SampleIterator in Blöcken fester
+// Größe, geeignet für effizienten RPC-Transport.
+type BatchIterator struct {
+	it        SampleIterator
+	batchSize int
+	batch     []Sample
+}
+
+// NewBatchIterator bündelt die Samples von it in Blöcken der Größe batchSize.
+func NewBatchIterator(it SampleIterator, batchSize int) *BatchIterator {
+	if batchSize <= 0 {
+		batchSize = 1
+	}
+	return &BatchIterator{it: it, batchSize: batchSize}
+}
+
+// Next füllt den nächsten Block und gibt false zurück, sobald keine Samples
+// mehr übrig sind.
+func (b *BatchIterator) Next() bool {
+	b.batch = b.batch[:0]
+	for len(b.batch) < b.batchSize && b.it.Next() {
+		b.batch = append(b.batch, b.it.At())
+	}
+	return len(b.batch) > 0
+}
+
+// At liefert den zuletzt gefüllten Block.
+func (b *BatchIterator) At() []Sample { return b.batch }
+
+// Close schließt den zugrunde liegenden Iterator.
+func (b *BatchIterator) Close() error { return b.it.Close() }
@@ -0,0 +1,111 @@
+package parser
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMergeIteratorSumsOverlappingSamples(t *testing.T) {
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	shardA := &InitialGroup{Signature: "Block <*> received", Samples: NewSampleRing(10 * time.Second)}
+	shardA.Samples.Add(base, 1, 10)
+	shardA.Samples.Add(base.Add(10*time.Second), 1, 10)
+
+	shardB := &InitialGroup{Signature: "Block <*> received", Samples: NewSampleRing(10 * time.Second)}
+	shardB.Samples.Add(base, 1, 20)
+
+	merged := NewMergeIterator(NewInitialGroupIterator(shardA), NewInitialGroupIterator(shardB))
+	defer merged.Close()
+
+	var got []Sample
+	for merged.Next() {
+		got = append(got, merged.At())
+	}
+
+	if len(got) != 2 {
+		t.Fatalf("expected 2 merged samples, got %d", len(got))
+	}
+	if got[0].Count != 2 || got[0].Bytes != 30 {
+		t.Errorf("expected first bucket to sum to Count=2, Bytes=30, got %+v", got[0])
+	}
+	if got[1].Count != 1 || got[1].Bytes != 10 {
+		t.Errorf("expected second bucket Count=1, Bytes=10, got %+v", got[1])
+	}
+}
+
+func TestMergeIteratorGroupsInterleavedLabelsAtSameTimestamp(t *testing.T) {
+	// Regression: A, B, A, B, A an identischem Zeitstempel musste bisher zwei
+	// separate "A"-Samples mit einem "B" dazwischen liefern, statt ein
+	// einziges aufsummiertes "A"-Sample.
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	newSingleSampleGroup := func(signature string, bytes int64) *InitialGroup {
+		ig := &InitialGroup{Signature: signature, Samples: NewSampleRing(10 * time.Second)}
+		ig.Samples.Add(base, 1, bytes)
+		return ig
+	}
+
+	sources := []*InitialGroup{
+		newSingleSampleGroup("A", 1),
+		newSingleSampleGroup("B", 2),
+		newSingleSampleGroup("A", 3),
+		newSingleSampleGroup("B", 4),
+		newSingleSampleGroup("A", 5),
+	}
+
+	iters := make([]SampleIterator, len(sources))
+	for i, src := range sources {
+		iters[i] = NewInitialGroupIterator(src)
+	}
+
+	merged := NewMergeIterator(iters...)
+	defer merged.Close()
+
+	type labeledSample struct {
+		label  string
+		sample Sample
+	}
+	var got []labeledSample
+	for merged.Next() {
+		got = append(got, labeledSample{label: merged.Labels(), sample: merged.At()})
+	}
+
+	if len(got) != 2 {
+		t.Fatalf("expected 2 merged samples (one per label), got %d: %+v", len(got), got)
+	}
+
+	byLabel := make(map[string]Sample)
+	for _, g := range got {
+		byLabel[g.label] = g.sample
+	}
+
+	if s, ok := byLabel["A"]; !ok || s.Count != 3 || s.Bytes != 9 {
+		t.Errorf("expected label A to merge into Count=3, Bytes=9, got %+v (present=%v)", s, ok)
+	}
+	if s, ok := byLabel["B"]; !ok || s.Count != 2 || s.Bytes != 6 {
+		t.Errorf("expected label B to merge into Count=2, Bytes=6, got %+v (present=%v)", s, ok)
+	}
+}
+
+func TestBatchIterator(t *testing.T) {
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	ig := &InitialGroup{Signature: "Block <*> received", Samples: NewSampleRing(10 * time.Second)}
+	for i := 0; i < 5; i++ {
+		ig.Samples.Add(base.Add(time.Duration(i)*10*time.Second), 1, 10)
+	}
+
+	batches := NewBatchIterator(NewInitialGroupIterator(ig), 2)
+
+	var sizes []int
+	for batches.Next() {
+		sizes = append(sizes, len(batches.At()))
+	}
+
+	if len(sizes) != 3 {
+		t.Fatalf("expected 3 batches, got %d", len(sizes))
+	}
+	if sizes[0] != 2 || sizes[1] != 2 || sizes[2] != 1 {
+		t.Errorf("expected batch sizes [2 2 1], got %v", sizes)
+	}
+}
@@ -0,0 +1,241 @@
+package parser
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// defaultMetricsStep ist die Bucket-Größe, mit der neue SampleRings angelegt
+// werden, wenn keine explizite Schrittweite übergeben wird.
+const defaultMetricsStep = 10 * time.Second
+
+// Sample ist ein einzelner Messpunkt für eine Signatur: Anzahl Log-Zeilen und
+// Gesamt-Bytes in einem Zeit-Bucket.
+type Sample struct {
+	Timestamp time.Time
+	Count     int64
+	Bytes     int64
+}
+
+// SampleRing ist ein append-only Ringspeicher aufsteigend sortierter Samples
+// für eine einzelne Signatur, gebündelt in Schritten von step.
+type SampleRing struct {
+	step    time.Duration
+	samples []Sample
+}
+
+// NewSampleRing erstellt einen leeren SampleRing mit der gegebenen
+// Bucket-Größe. Bei step <= 0 wird defaultMetricsStep verwendet.
+func NewSampleRing(step time.Duration) *SampleRing {
+	if step <= 0 {
+		step = defaultMetricsStep
+	}
+	return &SampleRing{step: step}
+}
+
+// Add zählt count Log-Zeilen mit insgesamt bytes Bytes in den Bucket ein, zu
+// dem ts gehört. Da Samples fortlaufend und zeitlich aufsteigend eintreffen,
+// wird der letzte Eintrag aktualisiert, falls er denselben Bucket betrifft.
+func (r *SampleRing) Add(ts time.Time, count, bytes int64) {
+	bucket := ts.Truncate(r.step)
+	if n := len(r.samples); n > 0 && r.samples[n-1].Timestamp.Equal(bucket) {
+		r.samples[n-1].Count += count
+		r.samples[n-1].Bytes += bytes
+		return
+	}
+	r.samples = append(r.samples, Sample{Timestamp: bucket, Count: count, Bytes: bytes})
+}
+
+// Range liefert alle Samples im Intervall [start, end).
+func (r *SampleRing) Range(start, end time.Time) []Sample {
+	lo := sort.Search(len(r.samples), func(i int) bool { return !r.samples[i].Timestamp.Before(start) })
+	hi := sort.Search(len(r.samples), func(i int) bool { return !r.samples[i].Timestamp.Before(end) })
+	out := make([]Sample, hi-lo)
+	copy(out, r.samples[lo:hi])
+	return out
+}
+
+// MergeSampleRings kombiniert die Zeitreihen mehrerer Parser-Shards zu einem
+// einzigen, nach Zeitstempel sortierten SampleRing und summiert Samples, die
+// denselben Bucket betreffen.
+func MergeSampleRings(rings ...*SampleRing) *SampleRing {
+	merged := NewSampleRing(defaultMetricsStep)
+	if len(rings) > 0 {
+		merged.step = rings[0].step
+	}
+
+	byBucket := make(map[int64]*Sample)
+	var order []int64
+	for _, r := range rings {
+		for _, s := range r.samples {
+			key := s.Timestamp.UnixNano()
+			existing, ok := byBucket[key]
+			if !ok {
+				existing = &Sample{Timestamp: s.Timestamp}
+				byBucket[key] = existing
+				order = append(order, key)
+			}
+			existing.Count += s.Count
+			existing.Bytes += s.Bytes
+		}
+	}
+
+	sort.Slice(order, func(i, j int) bool { return order[i] < order[j] })
+	for _, key := range order {
+		merged.samples = append(merged.samples, *byBucket[key])
+	}
+	return merged
+}
+
+// recordSample schreibt ein Sample für signature in lp.Metrics und legt den
+// SampleRing bei Bedarf an. Für Aufrufer, deren Signatur über die Zeit
+// stabil bleibt (z.B. direkte, nicht-streamende Nutzung oder der Merge
+// mehrerer Shards). Stream verwendet stattdessen setMetric/retireMetric, da
+// GroupByLCP dort Einträge zwischen Signaturen verschieben kann.
+func (lp *LogParser) recordSample(signature string, ts time.Time, count, bytes int64) {
+	lp.metricsMu.Lock()
+	defer lp.metricsMu.Unlock()
+
+	if lp.Metrics == nil {
+		lp.Metrics = make(map[string]*SampleRing)
+	}
+	ring, ok := lp.Metrics[signature]
+	if !ok {
+		ring = NewSampleRing(defaultMetricsStep)
+		lp.Metrics[signature] = ring
+	}
+	ring.Add(ts, count, bytes)
+}
+
+// buildSampleRing baut einen neuen SampleRing vollständig aus den
+// Timestamp/Bytes-Angaben von logs auf, bucketed auf step. Im Gegensatz zu
+// recordSample/Add ist das ein Ersetzen statt eines fortlaufenden Zählens,
+// geeignet für Aufrufer wie Stream, die eine Gruppe bei jeder Neuberechnung
+// komplett neu aufbauen.
+func buildSampleRing(logs []*LogEntry, step time.Duration) *SampleRing {
+	sorted := make([]*LogEntry, len(logs))
+	copy(sorted, logs)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Timestamp.Before(sorted[j].Timestamp) })
+
+	ring := NewSampleRing(step)
+	for _, entry := range sorted {
+		ring.Add(entry.Timestamp, 1, entry.Bytes)
+	}
+	return ring
+}
+
+// setMetric setzt den SampleRing für signature auf ring und ersetzt einen
+// zuvor hinterlegten Ring vollständig. Wird von Stream genutzt, um
+// lp.Metrics nach jeder GroupByLCP-Neuberechnung mit der aktuell gültigen
+// Signatur synchron zu halten.
+func (lp *LogParser) setMetric(signature string, ring *SampleRing) {
+	lp.metricsMu.Lock()
+	defer lp.metricsMu.Unlock()
+
+	if lp.Metrics == nil {
+		lp.Metrics = make(map[string]*SampleRing)
+	}
+	lp.Metrics[signature] = ring
+}
+
+// retireMetric entfernt die Metrik einer Signatur, die nicht mehr produziert
+// wird (z.B. weil GroupByLCP ihre Logs mit einer anderen Signatur gemergt
+// oder in Kindsignaturen aufgesplittet hat), damit QueryRange keine für
+// immer eingefrorene, veraltete Historie mehr liefert.
+func (lp *LogParser) retireMetric(signature string) {
+	lp.metricsMu.Lock()
+	defer lp.metricsMu.Unlock()
+
+	delete(lp.Metrics, signature)
+}
+
+// QueryRange aggregiert die Samples einer Signatur im Intervall [start, end)
+// neu auf die Schrittweite step und wendet die Operation op an
+// ("count_over_time" oder "bytes_over_time").
+func (lp *LogParser) QueryRange(signature string, start, end time.Time, step time.Duration, op string) ([]Sample, error) {
+	if op != "count_over_time" && op != "bytes_over_time" {
+		return nil, fmt.Errorf("parser: unknown op %q", op)
+	}
+
+	lp.metricsMu.Lock()
+	defer lp.metricsMu.Unlock()
+
+	ring, ok := lp.Metrics[signature]
+	if !ok {
+		return nil, nil
+	}
+	if step <= 0 {
+		step = ring.step
+	}
+
+	buckets := make(map[int64]*Sample)
+	var order []int64
+	for _, s := range ring.Range(start, end) {
+		bucket := s.Timestamp.Truncate(step)
+		key := bucket.UnixNano()
+		agg, exists := buckets[key]
+		if !exists {
+			agg = &Sample{Timestamp: bucket}
+			buckets[key] = agg
+			order = append(order, key)
+		}
+		agg.Count += s.Count
+		agg.Bytes += s.Bytes
+	}
+	sort.Slice(order, func(i, j int) bool { return order[i] < order[j] })
+
+	out := make([]Sample, 0, len(order))
+	for _, key := range order {
+		sample := *buckets[key]
+		if op == "count_over_time" {
+			sample.Bytes = 0
+		} else {
+			sample.Count = 0
+		}
+		out = append(out, sample)
+	}
+	return out, nil
+}
+
+// MatchTemplates wählt alle bekannten Signaturen aus, die auf das
+// Wildcard-Pattern pattern passen ("*" steht für eine beliebige
+// Zeichenfolge).
+func (lp *LogParser) MatchTemplates(pattern string) []string {
+	lp.metricsMu.Lock()
+	defer lp.metricsMu.Unlock()
+
+	var matches []string
+	for signature := range lp.Metrics {
+		if matchWildcard(pattern, signature) {
+			matches = append(matches, signature)
+		}
+	}
+	sort.Strings(matches)
+	return matches
+}
+
+// matchWildcard prüft, ob value zum Glob-Pattern pattern passt, wobei "*"
+// eine beliebige (auch leere) Zeichenfolge ersetzt.
+func matchWildcard(pattern, value string) bool {
+	parts := strings.Split(pattern, "*")
+	if len(parts) == 1 {
+		return pattern == value
+	}
+
+	if !strings.HasPrefix(value, parts[0]) {
+		return false
+	}
+	value = value[len(parts[0]):]
+
+	for i := 1; i < len(parts)-1; i++ {
+		idx := strings.Index(value, parts[i])
+		if idx == -1 {
+			return false
+		}
+		value = value[idx+len(parts[i]):]
+	}
+
+	return strings.HasSuffix(value, parts[len(parts)-1])
+}
@@ -0,0 +1,75 @@
+package parser
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestQueryRangeAndMatchTemplates(t *testing.T) {
+	lp, err := NewLogParser([]string{`\d+`})
+	if err != nil {
+		t.Fatalf("Failed to create parser: %v", err)
+	}
+
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	lp.recordSample("Block <*> received", base, 1, 10)
+	lp.recordSample("Block <*> received", base.Add(5*time.Second), 1, 20)
+	lp.recordSample("Block <*> deleted", base, 1, 30)
+
+	samples, err := lp.QueryRange("Block <*> received", base, base.Add(time.Minute), 10*time.Second, "count_over_time")
+	if err != nil {
+		t.Fatalf("QueryRange failed: %v", err)
+	}
+	if len(samples) != 1 {
+		t.Fatalf("expected both samples to merge into 1 bucket, got %d", len(samples))
+	}
+	if samples[0].Count != 2 {
+		t.Errorf("expected Count 2, got %d", samples[0].Count)
+	}
+	if samples[0].Bytes != 0 {
+		t.Errorf("expected Bytes zeroed for count_over_time, got %d", samples[0].Bytes)
+	}
+
+	samples, err = lp.QueryRange("Block <*> received", base, base.Add(time.Minute), 10*time.Second, "bytes_over_time")
+	if err != nil {
+		t.Fatalf("QueryRange failed: %v", err)
+	}
+	if samples[0].Bytes != 30 {
+		t.Errorf("expected Bytes 30, got %d", samples[0].Bytes)
+	}
+
+	matches := lp.MatchTemplates("Block*")
+	if len(matches) != 2 {
+		t.Errorf("expected 2 templates to match wildcard, got %d (%v)", len(matches), matches)
+	}
+}
+
+// TestQueryRangeConcurrentWithRecordSample guards against a data race
+// between QueryRange reading a SampleRing and recordSample writing to it
+// concurrently; run with -race to verify.
+func TestQueryRangeConcurrentWithRecordSample(t *testing.T) {
+	lp, err := NewLogParser([]string{`\d+`})
+	if err != nil {
+		t.Fatalf("Failed to create parser: %v", err)
+	}
+
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	lp.recordSample("Block <*> received", base, 1, 10)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			lp.recordSample("Block <*> received", base.Add(time.Duration(i)*time.Second), 1, 10)
+		}(i)
+		go func() {
+			defer wg.Done()
+			if _, err := lp.QueryRange("Block <*> received", base, base.Add(time.Minute), 10*time.Second, "count_over_time"); err != nil {
+				t.Errorf("QueryRange failed: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,168 @@
+package parser
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultBucketDuration ist die Fenstergröße, die ein Stream verwendet, wenn
+// keine explizite bucketDuration übergeben wird.
+const defaultBucketDuration = 10 * time.Minute
+
+// defaultThresholdPercent ist der LCP-Schwellenwert, mit dem ein Stream seine
+// InitialGroups neu berechnet, wenn LogParser.Threshold nicht gesetzt (<= 0)
+// ist.
+const defaultThresholdPercent = 0.5
+
+// Chunk fasst alle Logs eines Zeitfensters zusammen und hält die daraus
+// abgeleiteten InitialGroups, damit nachgelagerter Code historische Buckets
+// durchgehen kann, ohne die Rohdaten erneut zu verarbeiten.
+type Chunk struct {
+	Start         time.Time
+	End           time.Time
+	Groups        map[int]*LogGroup                // Länge -> LogGroup für dieses Zeitfenster
+	InitialGroups map[int]map[string]*InitialGroup // Länge -> Signatur -> InitialGroup
+}
+
+// Stream verarbeitet Log-Zeilen fortlaufend und bündelt sie in zeitlich
+// begrenzte Chunks, analog zum Chunking des Pattern-Ingesters in Lokis
+// Drain-basierter Pipeline.
+type Stream struct {
+	lp             *LogParser
+	bucketDuration time.Duration
+
+	mu      sync.Mutex
+	current *Chunk
+	history []*Chunk
+}
+
+// NewStream erstellt einen Stream, der eingehende Log-Zeilen in Chunks der
+// Länge bucketDuration bündelt. Bei bucketDuration <= 0 wird ein
+// 10-Minuten-Fenster verwendet.
+func (lp *LogParser) NewStream(bucketDuration time.Duration) *Stream {
+	if bucketDuration <= 0 {
+		bucketDuration = defaultBucketDuration
+	}
+	return &Stream{lp: lp, bucketDuration: bucketDuration}
+}
+
+// Append nimmt eine Log-Zeile mit Zeitstempel entgegen, ordnet sie dem
+// passenden Chunk zu und berechnet dessen InitialGroups inkrementell neu, so
+// dass die Signaturen online aktuell bleiben. Liegt ts vor dem Start des
+// aktuell offenen Chunks, wird die Zeile trotzdem diesem Chunk hinzugefügt;
+// abgeschlossene Chunks werden nicht mehr verändert.
+func (s *Stream) Append(ts time.Time, line string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	bucketStart := ts.Truncate(s.bucketDuration)
+	if s.current == nil || bucketStart.After(s.current.Start) {
+		if s.current != nil {
+			s.history = append(s.history, s.current)
+		}
+		s.current = &Chunk{
+			Start:         bucketStart,
+			End:           bucketStart.Add(s.bucketDuration),
+			Groups:        make(map[int]*LogGroup),
+			InitialGroups: make(map[int]map[string]*InitialGroup),
+		}
+	}
+
+	s.ingest(s.current, ts, line)
+}
+
+// Flush schließt den aktuell offenen Chunk ab, auch wenn sein Zeitfenster
+// noch nicht abgelaufen ist, und hängt ihn an die Historie an.
+func (s *Stream) Flush() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.current == nil {
+		return
+	}
+	s.history = append(s.history, s.current)
+	s.current = nil
+}
+
+// Chunks liefert alle abgeschlossenen Chunks in chronologischer Reihenfolge.
+func (s *Stream) Chunks() []*Chunk {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	chunks := make([]*Chunk, len(s.history))
+	copy(chunks, s.history)
+	return chunks
+}
+
+// ingest fügt eine Zeile der passenden LogGroup des Chunks hinzu und
+// berechnet deren InitialGroups neu.
+func (s *Stream) ingest(chunk *Chunk, ts time.Time, line string) {
+	cleanLine := s.lp.Preprocess(line)
+	tokenStrings := strings.Fields(cleanLine)
+	length := len(tokenStrings)
+
+	group, exists := chunk.Groups[length]
+	if !exists {
+		group = &LogGroup{Length: length, Logs: []*LogEntry{}, ColumnCounts: make(map[int]map[string]int)}
+		chunk.Groups[length] = group
+	}
+
+	tokens := make([]LogToken, length)
+	for i, t := range tokenStrings {
+		tokens[i] = LogToken{Content: t}
+	}
+	entry := &LogEntry{LineID: len(group.Logs), Tokens: tokens, Timestamp: ts, Bytes: int64(len(line))}
+	group.Logs = append(group.Logs, entry)
+
+	for i, t := range tokenStrings {
+		if group.ColumnCounts[i] == nil {
+			group.ColumnCounts[i] = make(map[string]int)
+		}
+		group.ColumnCounts[i][t]++
+	}
+	for _, e := range group.Logs {
+		for i := range e.Tokens {
+			e.Tokens[i].Frequency = group.ColumnCounts[i][e.Tokens[i].Content]
+		}
+	}
+
+	initialGroups := group.GroupByLCP(s.threshold())
+	s.syncMetrics(chunk, length, initialGroups)
+	chunk.InitialGroups[length] = initialGroups
+}
+
+// syncMetrics hält die Samples-Zeitreihen mit der gerade von GroupByLCP neu
+// berechneten Signatur-Zuordnung synchron. Da GroupByLCP bei wachsenden
+// Gruppen Einträge zwischen Signaturen verschieben kann (genau das meint
+// "InitialGroup signatures are updated online"), lässt sich eine Historie
+// nicht inkrementell an einen Signatur-String binden: jede InitialGroup
+// bekommt stattdessen ihren SampleRing komplett neu aus ihren aktuellen Logs
+// aufgebaut (deren Timestamp/Bytes jede LogEntry selbst trägt), und
+// lp.Metrics zeigt für noch produzierte Signaturen auf genau diesen Ring.
+// Signaturen, die in dieser Runde verschwunden sind (weil ihre Logs in eine
+// andere Signatur gemergt/gesplittet wurden), werden aus lp.Metrics entfernt,
+// statt für immer eingefrorene, veraltete Historie zu behalten.
+func (s *Stream) syncMetrics(chunk *Chunk, length int, groups map[string]*InitialGroup) {
+	previous := chunk.InitialGroups[length]
+
+	for signature, ig := range groups {
+		ig.Samples = buildSampleRing(ig.Logs, defaultMetricsStep)
+		s.lp.setMetric(signature, ig.Samples)
+	}
+
+	for signature := range previous {
+		if _, stillProduced := groups[signature]; !stillProduced {
+			s.lp.retireMetric(signature)
+		}
+	}
+}
+
+// threshold liefert den LCP-Schwellenwert des zugrunde liegenden LogParser,
+// sofern gesetzt, und fällt andernfalls auf defaultThresholdPercent zurück.
+func (s *Stream) threshold() float64 {
+	if s.lp.Threshold > 0 {
+		return s.lp.Threshold
+	}
+	return defaultThresholdPercent
+}
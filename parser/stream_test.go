@@ -0,0 +1,176 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStreamAppendAccumulatesSamplesWithinOneChunk(t *testing.T) {
+	cases := []struct {
+		name      string
+		rawLogs   []string
+		wantCount int64
+	}{
+		{
+			name: "three lines sharing a signature within one bucket",
+			rawLogs: []string{
+				"blk_101 info: Block 101 received from 10.0.0.1",
+				"blk_102 info: Block 102 received from 10.0.0.2",
+				"blk_103 info: Block 103 received from 10.0.0.3",
+			},
+			wantCount: 3,
+		},
+	}
+
+	regexPatterns := []string{`blk_\d+`, `\d+\.\d+\.\d+\.\d+`, `\d+`}
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			lp, err := NewLogParser(regexPatterns, 0.5)
+			if err != nil {
+				t.Fatalf("Failed to create parser: %v", err)
+			}
+
+			stream := lp.NewStream(10 * time.Minute)
+			for i, line := range tc.rawLogs {
+				stream.Append(base.Add(time.Duration(i)*time.Second), line)
+			}
+			stream.Flush()
+
+			chunks := stream.Chunks()
+			if len(chunks) != 1 {
+				t.Fatalf("expected 1 chunk, got %d", len(chunks))
+			}
+
+			length := len(strings.Fields(lp.Preprocess(tc.rawLogs[0])))
+			if len(chunks[0].InitialGroups[length]) == 0 {
+				t.Fatalf("expected InitialGroups for length %d", length)
+			}
+			for _, ig := range chunks[0].InitialGroups[length] {
+				if ig.Samples == nil {
+					t.Fatalf("InitialGroup %q has nil Samples", ig.Signature)
+				}
+				samples := ig.Samples.Range(base.Add(-time.Minute), base.Add(time.Minute))
+				var total int64
+				for _, s := range samples {
+					total += s.Count
+				}
+				if total != tc.wantCount {
+					t.Errorf("signature %q: expected accumulated Count %d, got %d", ig.Signature, tc.wantCount, total)
+				}
+			}
+		})
+	}
+}
+
+func TestStreamMetricsStayConsistentAsSignaturesChurn(t *testing.T) {
+	// Regression: GroupByLCP reclassifies a growing group's logs under a new
+	// signature as more data arrives. Metrics (both chunk-local InitialGroup
+	// Samples and lp.Metrics/QueryRange) must follow the current signature,
+	// not stay keyed to one that GroupByLCP no longer produces.
+	regexPatterns := []string{`\d+`}
+	lp, err := NewLogParser(regexPatterns, 0.5)
+	if err != nil {
+		t.Fatalf("Failed to create parser: %v", err)
+	}
+
+	stream := lp.NewStream(time.Hour)
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	lines := []string{
+		"GET /alpha 200",
+		"GET /alpha 200",
+		"POST /beta 200",
+		"POST /beta 200",
+		"POST /beta 200",
+		"POST /beta 200",
+	}
+	for i, line := range lines {
+		stream.Append(base.Add(time.Duration(i)*time.Second), line)
+	}
+	stream.Flush()
+
+	chunks := stream.Chunks()
+	if len(chunks) != 1 {
+		t.Fatalf("expected 1 chunk, got %d", len(chunks))
+	}
+
+	initialGroups := chunks[0].InitialGroups[3]
+	if len(initialGroups) == 0 {
+		t.Fatalf("expected at least one InitialGroup for length 3")
+	}
+
+	liveSignatures := make(map[string]bool)
+	for signature, ig := range initialGroups {
+		liveSignatures[signature] = true
+
+		if ig.Samples == nil {
+			t.Fatalf("signature %q: expected non-nil chunk-local Samples", signature)
+		}
+		localSamples := ig.Samples.Range(base.Add(-time.Minute), base.Add(time.Hour))
+		var localTotal int64
+		for _, s := range localSamples {
+			localTotal += s.Count
+		}
+		if localTotal != int64(len(ig.Logs)) {
+			t.Errorf("signature %q: expected chunk-local Samples Count to match live log count %d, got %d", signature, len(ig.Logs), localTotal)
+		}
+
+		samples, err := lp.QueryRange(signature, base.Add(-time.Minute), base.Add(time.Hour), time.Hour, "count_over_time")
+		if err != nil {
+			t.Fatalf("QueryRange failed for %q: %v", signature, err)
+		}
+		var total int64
+		for _, s := range samples {
+			total += s.Count
+		}
+		if total != int64(len(ig.Logs)) {
+			t.Errorf("signature %q: expected QueryRange Count to match live log count %d, got %d", signature, len(ig.Logs), total)
+		}
+	}
+
+	for signature := range lp.Metrics {
+		if !liveSignatures[signature] {
+			t.Errorf("lp.Metrics kept a stale signature %q that GroupByLCP no longer produces", signature)
+		}
+	}
+}
+
+func TestStreamAppendRollsOverIntoNewChunks(t *testing.T) {
+	regexPatterns := []string{`\d+`}
+	lp, err := NewLogParser(regexPatterns, 0.5)
+	if err != nil {
+		t.Fatalf("Failed to create parser: %v", err)
+	}
+
+	stream := lp.NewStream(time.Minute)
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	stream.Append(base, "User 1 login")
+	stream.Append(base.Add(30*time.Second), "User 2 login")
+	stream.Append(base.Add(2*time.Minute), "User 3 login")
+	stream.Flush()
+
+	chunks := stream.Chunks()
+	if len(chunks) != 2 {
+		t.Fatalf("expected 2 chunks after rollover and flush, got %d", len(chunks))
+	}
+
+	group3, ok := chunks[0].Groups[3]
+	if !ok {
+		t.Fatalf("expected length-3 group in first chunk")
+	}
+	if len(group3.Logs) != 2 {
+		t.Errorf("expected first chunk to hold the first 2 logs, got %d", len(group3.Logs))
+	}
+
+	group3, ok = chunks[1].Groups[3]
+	if !ok {
+		t.Fatalf("expected length-3 group in second chunk")
+	}
+	if len(group3.Logs) != 1 {
+		t.Errorf("expected second chunk to hold the third log, got %d", len(group3.Logs))
+	}
+}
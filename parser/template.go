@@ -0,0 +1,154 @@
+package parser
+
+import (
+	"sort"
+	"strings"
+)
+
+// Template repräsentiert ein final abgeleitetes Log-Muster (Schritt 3 des
+// Brain-Algorithmus, das hierarchische Merging von InitialGroups).
+type Template struct {
+	Signature string      // Finale Signatur mit "<*>" an Variablenpositionen
+	Entries   []*LogEntry // Die zu diesem Template beitragenden LogEntrys
+	Parent    *Template
+	Children  []*Template
+}
+
+// FindWordCombinations führt Schritt 3 des Brain-Algorithmus aus: Für jede
+// LogGroup werden zunächst die InitialGroups (Schritt 2) gebildet, danach
+// wird jede InitialGroup so lange nach oben (Spalte hat über die ganze
+// Gruppe denselben Wert, dessen Häufigkeit gleich der RootFrequency ist ->
+// Teil des Patterns) bzw. nach unten (Spaltenhäufigkeit
+// ist ein echter Teiler der RootFrequency -> Gruppe aufsplitten) gemergt,
+// bis sich keine InitialGroup mehr verändert.
+func (lp *LogParser) FindWordCombinations(groups map[int]*LogGroup) []*Template {
+	var templates []*Template
+
+	lengths := make([]int, 0, len(groups))
+	for length := range groups {
+		lengths = append(lengths, length)
+	}
+	sort.Ints(lengths)
+
+	for _, length := range lengths {
+		initialGroups := groups[length].GroupByLCP(lp.Threshold)
+
+		signatures := make([]string, 0, len(initialGroups))
+		for sig := range initialGroups {
+			signatures = append(signatures, sig)
+		}
+		sort.Strings(signatures)
+
+		for _, sig := range signatures {
+			templates = append(templates, lp.buildTemplate(initialGroups[sig], nil))
+		}
+	}
+
+	return templates
+}
+
+// buildTemplate wendet das Upward-/Downward-Merging auf ig an und rekursiert
+// in die dabei entstehenden Kindgruppen.
+func (lp *LogParser) buildTemplate(ig *InitialGroup, parent *Template) *Template {
+	sigTokens := strings.Split(ig.Signature, " ")
+	columnCounts := variableColumnFrequencies(ig.Logs, sigTokens)
+
+	// Upward merge: Spalten, die über die ganze Gruppe denselben Wert mit
+	// Häufigkeit gleich RootFrequency haben, gehören zum Pattern.
+	for col, counts := range columnCounts {
+		if len(counts) != 1 {
+			continue
+		}
+		for value, count := range counts {
+			if count == ig.RootFrequency {
+				sigTokens[col] = value
+			}
+		}
+	}
+	ig.Signature = strings.Join(sigTokens, " ")
+
+	t := &Template{Signature: ig.Signature, Entries: ig.Logs, Parent: parent}
+
+	// Downward merge: die erste (niedrigste Spaltennummer) Spalte, deren
+	// häufigster Wert ein echter Teiler der RootFrequency ist, teilt die
+	// Gruppe in Kinder auf.
+	col, ok := findDownwardColumn(columnCounts, ig.RootFrequency)
+	if !ok {
+		return t
+	}
+
+	for _, child := range splitByColumn(ig, col) {
+		t.Children = append(t.Children, lp.buildTemplate(child, t))
+	}
+	return t
+}
+
+// variableColumnFrequencies zählt für jede noch nicht festgelegte Position
+// (sigTokens[i] == "<*>") die Häufigkeit der dort vorkommenden Wörter.
+func variableColumnFrequencies(logs []*LogEntry, sigTokens []string) map[int]map[string]int {
+	counts := make(map[int]map[string]int)
+	for _, entry := range logs {
+		for i, token := range entry.Tokens {
+			if i >= len(sigTokens) || sigTokens[i] != "<*>" {
+				continue
+			}
+			if counts[i] == nil {
+				counts[i] = make(map[string]int)
+			}
+			counts[i][token.Content]++
+		}
+	}
+	return counts
+}
+
+// findDownwardColumn sucht die Spalte mit der niedrigsten Spaltennummer,
+// deren häufigster Wert echt unter rootFreq liegt und diese gleichmäßig
+// teilt.
+func findDownwardColumn(columnCounts map[int]map[string]int, rootFreq int) (int, bool) {
+	cols := make([]int, 0, len(columnCounts))
+	for col := range columnCounts {
+		cols = append(cols, col)
+	}
+	sort.Ints(cols)
+
+	for _, col := range cols {
+		maxFreq := 0
+		for _, count := range columnCounts[col] {
+			if count > maxFreq {
+				maxFreq = count
+			}
+		}
+		if maxFreq > 0 && maxFreq < rootFreq && rootFreq%maxFreq == 0 {
+			return col, true
+		}
+	}
+	return 0, false
+}
+
+// splitByColumn teilt ig anhand des Werts in Spalte col in Kindgruppen auf.
+// Die Signatur der Kinder bleibt zunächst unverändert (Spalte col bleibt
+// "<*>") - der nächste buildTemplate-Aufruf erkennt sie via Upward-Merge
+// automatisch als konstant, da sie innerhalb jedes Kindes eindeutig ist.
+func splitByColumn(ig *InitialGroup, col int) []*InitialGroup {
+	byValue := make(map[string]*InitialGroup)
+	var order []string
+
+	for _, entry := range ig.Logs {
+		value := entry.Tokens[col].Content
+		child, exists := byValue[value]
+		if !exists {
+			child = &InitialGroup{Signature: ig.Signature}
+			byValue[value] = child
+			order = append(order, value)
+		}
+		child.Logs = append(child.Logs, entry)
+	}
+
+	children := make([]*InitialGroup, 0, len(order))
+	for _, value := range order {
+		child := byValue[value]
+		child.RootFrequency = len(child.Logs)
+		children = append(children, child)
+	}
+	return children
+}
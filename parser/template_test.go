@@ -0,0 +1,74 @@
+package parser
+
+import "testing"
+
+func TestFindWordCombinations(t *testing.T) {
+	cases := []struct {
+		name     string
+		rawLogs  []string
+		wantSigs []string
+	}{
+		{
+			name: "HDFS block received/deleted splits on the varying verb",
+			rawLogs: []string{
+				"blk_101 info: Block 101 received from 10.0.0.1",
+				"blk_102 info: Block 102 received from 10.0.0.2",
+				"blk_103 info: Block 103 deleted from 10.0.0.3",
+				"blk_104 info: Block 104 deleted from 10.0.0.4",
+			},
+			wantSigs: []string{
+				"<*> info: Block <*> received from <*>",
+				"<*> info: Block <*> deleted from <*>",
+			},
+		},
+	}
+
+	regexPatterns := []string{`blk_\d+`, `\d+\.\d+\.\d+\.\d+`, `\d+`}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			lp, err := NewLogParser(regexPatterns, 0.5)
+			if err != nil {
+				t.Fatalf("Failed to create parser: %v", err)
+			}
+
+			groups := lp.Vectorize(tc.rawLogs)
+			templates := lp.FindWordCombinations(groups)
+
+			got := leafSignatures(templates)
+			for _, want := range tc.wantSigs {
+				if !containsString(got, want) {
+					t.Errorf("expected signature %q among templates, got %v", want, got)
+				}
+			}
+		})
+	}
+}
+
+// leafSignatures sammelt die Signaturen aller Blatt-Templates (ohne Kinder).
+func leafSignatures(templates []*Template) []string {
+	var sigs []string
+	var walk func(t *Template)
+	walk = func(t *Template) {
+		if len(t.Children) == 0 {
+			sigs = append(sigs, t.Signature)
+			return
+		}
+		for _, child := range t.Children {
+			walk(child)
+		}
+	}
+	for _, t := range templates {
+		walk(t)
+	}
+	return sigs
+}
+
+func containsString(haystack []string, needle string) bool {
+	for _, s := range haystack {
+		if s == needle {
+			return true
+		}
+	}
+	return false
+}